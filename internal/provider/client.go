@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// ProviderHTTPClient is the set of HTTP operations AAP resources need from the provider's
+// configured client.
+type ProviderHTTPClient interface {
+	Get(path string) ([]byte, error)
+	GetWithStatus(path string) ([]byte, diag.Diagnostics, int)
+	doRequest(method string, path string, body io.Reader) (*http.Response, []byte, error)
+	getApiEndpoint() string
+}
+
+// AAPClient is the provider's configured client for talking to an AAP controller.
+type AAPClient struct {
+	HostURL     string
+	Username    string
+	Password    string
+	httpClient  *http.Client
+	eventsWSURL string
+}
+
+// NewAAPClient builds an AAPClient for the given controller host, credentials, and
+// optional events_ws_url. insecureSkipVerify disables TLS certificate verification, for
+// controllers using a self-signed certificate.
+func NewAAPClient(hostURL, username, password string, insecureSkipVerify bool, eventsWSURL string) *AAPClient {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify}, //nolint:gosec
+	}
+
+	return &AAPClient{
+		HostURL:     strings.TrimSuffix(hostURL, "/"),
+		Username:    username,
+		Password:    password,
+		httpClient:  &http.Client{Transport: transport},
+		eventsWSURL: eventsWSURL,
+	}
+}
+
+// EventsWSURL returns the websocket URL configured via the provider's events_ws_url
+// attribute, or "" if it wasn't set. It satisfies eventsWSURLProvider, letting resources
+// opt into event-driven waits instead of plain backoff polling.
+func (c *AAPClient) EventsWSURL() string {
+	return c.eventsWSURL
+}
+
+func (c *AAPClient) getApiEndpoint() string {
+	return c.HostURL + "/api/v2"
+}
+
+// doRequest issues an HTTP request against the controller, authenticating with the
+// configured username and password.
+func (c *AAPClient) doRequest(method string, path string, body io.Reader) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating request: %s", err)
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error performing request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, fmt.Errorf("error reading response body: %s", err)
+	}
+	return resp, respBody, nil
+}
+
+// Get issues a GET request against path and returns the response body, failing on
+// anything other than a 200 status.
+func (c *AAPClient) Get(path string) ([]byte, error) {
+	resp, body, err := c.doRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, path, string(body))
+	}
+	return body, nil
+}
+
+// GetWithStatus issues a GET request against path and returns the response body, the
+// status code, and any diagnostics from a non-404 error, so callers can special-case a
+// 404 (e.g. to recreate a resource that's gone missing) without treating it as an error.
+func (c *AAPClient) GetWithStatus(path string) ([]byte, diag.Diagnostics, int) {
+	var diags diag.Diagnostics
+
+	resp, body, err := c.doRequest(http.MethodGet, path, nil)
+	if err != nil {
+		diags.AddError("Error making GET request", err.Error())
+		return nil, diags, 0
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		diags.AddError(
+			"Unexpected HTTP status code",
+			fmt.Sprintf("Expected 200 or 404 from %s, got %d: %s", path, resp.StatusCode, string(body)),
+		)
+		return nil, diags, resp.StatusCode
+	}
+
+	return body, diags, resp.StatusCode
+}
+
+// ValidateResponse checks that err is nil, resp is non-nil, and resp's status code is one
+// of validStatusCodes, returning a diagnostic describing the problem otherwise.
+func ValidateResponse(resp *http.Response, body []byte, err error, validStatusCodes []int) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if err != nil {
+		diags.AddError("Error making request", err.Error())
+		return diags
+	}
+	if resp == nil {
+		diags.AddError("Error making request", "response was nil")
+		return diags
+	}
+
+	for _, code := range validStatusCodes {
+		if resp.StatusCode == code {
+			return diags
+		}
+	}
+
+	diags.AddError(
+		"Unexpected HTTP status code",
+		fmt.Sprintf("Expected one of %v, got %d: %s", validStatusCodes, resp.StatusCode, string(bytes.TrimSpace(body))),
+	)
+	return diags
+}
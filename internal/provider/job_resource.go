@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/ansible/terraform-provider-aap/internal/provider/customtypes"
@@ -18,13 +19,25 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Default value for the wait_for_completion timeout, so the linter doesn't complain.
 const waitForCompletionTimeoutDefault int64 = 120
 
+// Number of bytes of job stdout to include in the failure diagnostic, so a large
+// playbook's output doesn't get dumped in full into the Terraform error message.
+const jobStdoutTailBytes = 4096
+
+// Statuses that, by default, are treated as a failed job when wait_for_completion is set.
+var defaultFailOnStatus = types.SetValueMust(types.StringType, []attr.Value{
+	types.StringValue("failed"),
+	types.StringValue("error"),
+	types.StringValue("canceled"),
+})
+
 // Job AAP API model
 type JobAPIModel struct {
 	TemplateID    int64                  `json:"job_template,omitempty"`
@@ -49,11 +62,19 @@ type JobResourceModel struct {
 	WaitForCompletion        types.Bool                       `tfsdk:"wait_for_completion"`
 	WaitForCompletionTimeout types.Int64                      `tfsdk:"wait_for_completion_timeout_seconds"`
 	DestroyJobTemplateID     types.Int64                      `tfsdk:"destroy_job_template_id"`
+	CancelOnDestroy          types.Bool                       `tfsdk:"cancel_on_destroy"`
+	FailOnStatus             types.Set                        `tfsdk:"fail_on_status"`
+	PollMaxIntervalSeconds   types.Int64                      `tfsdk:"poll_max_interval_seconds"`
 }
 
 // JobResource is the resource implementation.
 type JobResource struct {
 	client ProviderHTTPClient
+
+	// eventSubscriber delivers status_changed events for jobs launched by this resource,
+	// so waitForJobCompletion wakes immediately instead of always falling back to backoff
+	// polling. It is nil when the provider wasn't configured with events_ws_url.
+	eventSubscriber *jobEventSubscriber
 }
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -88,21 +109,23 @@ func IsFinalStateAAPJob(state string) bool {
 	return isPresent && result
 }
 
-func retryUntilAAPJobReachesAnyFinalState(client ProviderHTTPClient, model JobResourceModel, diagnostics diag.Diagnostics) retry.RetryFunc {
-	return func() *retry.RetryError {
+// waitForJobCompletion polls model.URL via client until the job reaches a final state or
+// ctx's wait_for_completion_timeout_seconds deadline passes, refreshing model in place on
+// every poll. It wraps the shared waitForJob helper with the JobResourceModel-specific
+// bits: parsing the HTTP response and logging progress.
+func waitForJobCompletion(ctx context.Context, client ProviderHTTPClient, model *JobResourceModel, pollMaxInterval time.Duration, subscriber *jobEventSubscriber, diagnostics *diag.Diagnostics) error {
+	return waitForJob(ctx, model.URL.ValueString(), pollMaxInterval, subscriber, func() (string, error) {
 		responseBody, err := client.Get(model.URL.ValueString())
-		diagnostics.Append(model.ParseHttpResponse(responseBody)...)
 		if err != nil {
-			return retry.RetryableError(fmt.Errorf("error fetching job status: %s", err))
+			return "", fmt.Errorf("error fetching job status: %s", err)
 		}
-		fmt.Printf("Job ID: %s, Current Status: %s\n", model.TemplateID, model.Status.ValueString())
-
-		if !IsFinalStateAAPJob(model.Status.ValueString()) {
-			return retry.RetryableError(fmt.Errorf("job at: %s hasn't yet reached a final state. Current state: %s", model.URL, model.Status.ValueString()))
-		} else {
-			return nil
-		}
-	}
+		diagnostics.Append(model.ParseHttpResponse(responseBody)...)
+		tflog.Debug(ctx, "polled AAP job status", map[string]interface{}{
+			"job_template_id": model.TemplateID.String(),
+			"status":          model.Status.ValueString(),
+		})
+		return model.Status.ValueString(), nil
+	})
 }
 
 // Metadata returns the resource type name.
@@ -128,6 +151,7 @@ func (r *JobResource) Configure(_ context.Context, req resource.ConfigureRequest
 	}
 
 	r.client = client
+	r.eventSubscriber = sharedJobEventSubscriber(client)
 }
 
 // Schema defines the schema for the  jobresource.
@@ -195,6 +219,30 @@ func (r *JobResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 				Description: "Id of the job template to run when the resource is destroyed. " +
 					"This allows running cleanup tasks before the resource is removed from the state.",
 			},
+			"fail_on_status": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Default:     setdefault.StaticValue(defaultFailOnStatus),
+				Description: "Set of job statuses that, when reached while `wait_for_completion` is `true`, " +
+					"cause Terraform to treat the job as failed. Defaults to `[\"failed\", \"error\", \"canceled\"]`.",
+			},
+			"poll_max_interval_seconds": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(pollMaxIntervalDefault),
+				Description: "Caps the exponential backoff between job status polls while waiting for " +
+					"completion. Polling starts at 1 second and doubles (with +/-20% jitter) up to this " +
+					"value between each poll. Default value of `30`.",
+			},
+			"cancel_on_destroy": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				Description: "When this is set to `true`, Terraform will cancel the job on the controller " +
+					"when the resource is destroyed, instead of leaving it running. Terraform will wait for " +
+					"the job to reach a final state, subject to `wait_for_completion_timeout_seconds`.",
+			},
 		},
 		MarkdownDescription: "Launches an AAP job.\n\n" +
 			"A job is launched only when the resource is first created or when the " +
@@ -205,10 +253,16 @@ func (r *JobResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 			"from the state. However, if `destroy_job_template_id` is specified, " +
 			"it will launch that job template during resource destruction, allowing " +
 			"you to run cleanup tasks before the resource is removed.\n\n" +
+			"Alternatively, if `cancel_on_destroy` is set to `true`, the job created by this " +
+			"resource will be canceled on the controller when the resource is destroyed, " +
+			"rather than being left to run.\n\n" +
 			"Moreover, you can set `wait_for_completion` to true, then Terraform will " +
 			"wait until this job is created and reaches any final state before continuing. " +
 			"This parameter works in both create and update operations.\n\n" +
-			"You can also tweak `wait_for_completion_timeout_seconds` to control the timeout limit.",
+			"You can also tweak `wait_for_completion_timeout_seconds` to control the timeout limit.\n\n" +
+			"While waiting, if the job reaches a status listed in `fail_on_status` (by default, " +
+			"`failed`, `error`, or `canceled`), Terraform will fail the apply and report the job's " +
+			"stdout instead of treating the job as successfully created.",
 	}
 }
 
@@ -229,11 +283,12 @@ func (r *JobResource) Create(ctx context.Context, req resource.CreateRequest, re
 	// If the job was configured to wait for completion, start polling the job status
 	// and wait for it to complete before marking the resource as created
 	if data.WaitForCompletion.ValueBool() {
-		timeout := time.Duration(data.WaitForCompletionTimeout.ValueInt64()) * time.Second
-		err := retry.RetryContext(ctx, timeout, retryUntilAAPJobReachesAnyFinalState(r.client, data, resp.Diagnostics))
-		if err != nil {
-			resp.Diagnostics.Append(diag.NewErrorDiagnostic("error when waiting for AAP job to complete", err.Error()))
+		r.waitForCompletion(ctx, &data, "error when waiting for AAP job to complete", &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+
+		r.reportJobFailureIfNeeded(&data, &resp.Diagnostics)
 		if resp.Diagnostics.HasError() {
 			return
 		}
@@ -305,11 +360,12 @@ func (r *JobResource) Update(ctx context.Context, req resource.UpdateRequest, re
 	// If the job was configured to wait for completion, start polling the job status
 	// and wait for it to complete before marking the resource as created
 	if data.WaitForCompletion.ValueBool() {
-		timeout := time.Duration(data.WaitForCompletionTimeout.ValueInt64()) * time.Second
-		err := retry.RetryContext(ctx, timeout, retryUntilAAPJobReachesAnyFinalState(r.client, data, resp.Diagnostics))
-		if err != nil {
-			resp.Diagnostics.Append(diag.NewErrorDiagnostic("error when waiting for AAP job to complete", err.Error()))
+		r.waitForCompletion(ctx, &data, "error when waiting for AAP job to complete", &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+
+		r.reportJobFailureIfNeeded(&data, &resp.Diagnostics)
 		if resp.Diagnostics.HasError() {
 			return
 		}
@@ -332,6 +388,21 @@ func (r JobResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 		return
 	}
 
+	// If cancel_on_destroy is enabled, cancel the job running on the controller instead of
+	// leaving it to run after the resource has been removed from state. A job that's
+	// already reached a final state has nothing to cancel, and AAP returns 405 for it.
+	if data.CancelOnDestroy.ValueBool() && data.URL.ValueString() != "" && !IsFinalStateAAPJob(data.Status.ValueString()) {
+		resp.Diagnostics.Append(r.CancelJob(&data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		r.waitForCompletion(ctx, &data, "error when waiting for canceled AAP job to reach a final state", &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// If destroy_job_template_id is specified, launch that job template
 	if !data.DestroyJobTemplateID.IsNull() && data.DestroyJobTemplateID.ValueInt64() > 0 {
 		// Create a temporary model for the destroy job
@@ -341,6 +412,7 @@ func (r JobResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 			ExtraVars:                data.ExtraVars,
 			WaitForCompletion:        data.WaitForCompletion,
 			WaitForCompletionTimeout: data.WaitForCompletionTimeout,
+			PollMaxIntervalSeconds:   data.PollMaxIntervalSeconds,
 		}
 
 		// Launch the destroy job
@@ -351,11 +423,7 @@ func (r JobResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 
 		// If configured to wait for completion, wait for the destroy job to finish
 		if destroyJobData.WaitForCompletion.ValueBool() {
-			timeout := time.Duration(destroyJobData.WaitForCompletionTimeout.ValueInt64()) * time.Second
-			err := retry.RetryContext(ctx, timeout, retryUntilAAPJobReachesAnyFinalState(r.client, destroyJobData, resp.Diagnostics))
-			if err != nil {
-				resp.Diagnostics.Append(diag.NewErrorDiagnostic("error when waiting for destroy job to complete", err.Error()))
-			}
+			r.waitForCompletion(ctx, &destroyJobData, "error when waiting for destroy job to complete", &resp.Diagnostics)
 			if resp.Diagnostics.HasError() {
 				return
 			}
@@ -363,6 +431,21 @@ func (r JobResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 	}
 }
 
+// waitForCompletion waits for data's job to reach a final state, honoring
+// wait_for_completion_timeout_seconds and poll_max_interval_seconds, and appends summary
+// as an error diagnostic if the wait itself fails (times out, loses the connection, etc).
+func (r *JobResource) waitForCompletion(ctx context.Context, data *JobResourceModel, summary string, diagnostics *diag.Diagnostics) {
+	timeout := time.Duration(data.WaitForCompletionTimeout.ValueInt64()) * time.Second
+	pollMaxInterval := time.Duration(data.PollMaxIntervalSeconds.ValueInt64()) * time.Second
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := waitForJobCompletion(waitCtx, r.client, data, pollMaxInterval, r.eventSubscriber, diagnostics); err != nil {
+		diagnostics.Append(diag.NewErrorDiagnostic(summary, err.Error()))
+	}
+}
+
 // CreateRequestBody creates a JSON encoded request body from the job resource data
 func (r *JobResourceModel) CreateRequestBody() ([]byte, diag.Diagnostics) {
 	var diags diag.Diagnostics
@@ -465,3 +548,62 @@ func (r *JobResource) LaunchJob(data *JobResourceModel) diag.Diagnostics {
 func (r *JobResourceModel) GetTemplateID() string {
 	return r.TemplateID.String()
 }
+
+// reportJobFailureIfNeeded checks the job's final status against fail_on_status and, if it
+// matches, fetches the job's stdout and appends a diagnostic error describing the failure.
+func (r *JobResource) reportJobFailureIfNeeded(data *JobResourceModel, diagnostics *diag.Diagnostics) {
+	status := data.Status.ValueString()
+	if !isJobStatusInFailOnStatus(data.FailOnStatus, status) {
+		return
+	}
+
+	stdout, err := r.GetJobStdout(data.URL.ValueString())
+	if err != nil {
+		diagnostics.AddError(
+			"AAP job did not complete successfully",
+			fmt.Sprintf("Job at %q ended with status %q. Additionally, failed to fetch job stdout: %s", data.URL.ValueString(), status, err),
+		)
+		return
+	}
+
+	diagnostics.AddError(
+		"AAP job did not complete successfully",
+		fmt.Sprintf("Job at %q ended with status %q.\n\nJob stdout:\n%s", data.URL.ValueString(), status, stdout),
+	)
+}
+
+// isJobStatusInFailOnStatus returns true if status is one of the values in the fail_on_status set.
+func isJobStatusInFailOnStatus(failOnStatus types.Set, status string) bool {
+	for _, v := range failOnStatus.Elements() {
+		if s, ok := v.(types.String); ok && s.ValueString() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// GetJobStdout fetches the tail of the job's stdout from AAP, for inclusion in the
+// diagnostic raised when a job reaches a status listed in fail_on_status.
+func (r *JobResource) GetJobStdout(jobURL string) (string, error) {
+	stdoutURL := strings.TrimSuffix(jobURL, "/") + "/stdout/?format=txt"
+	body, err := r.client.Get(stdoutURL)
+	if err != nil {
+		return "", err
+	}
+
+	if len(body) > jobStdoutTailBytes {
+		body = body[len(body)-jobStdoutTailBytes:]
+	}
+	return string(body), nil
+}
+
+// CancelJob issues a cancel request for the job tracked by this resource so it stops
+// running on the controller instead of being orphaned when the resource is destroyed.
+func (r *JobResource) CancelJob(data *JobResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cancelURL := strings.TrimSuffix(data.URL.ValueString(), "/") + "/cancel/"
+	resp, body, err := r.client.doRequest(http.MethodPost, cancelURL, nil)
+	diags.Append(ValidateResponse(resp, body, err, []int{http.StatusAccepted})...)
+	return diags
+}
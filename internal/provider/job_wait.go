@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// pollMaxIntervalDefault is the default cap, in seconds, for the exponential backoff
+	// between job status polls performed by waitForJob.
+	pollMaxIntervalDefault int64 = 30
+
+	waitForJobInitialBackoff = time.Second
+	waitForJobJitterFraction = 0.2
+)
+
+// waitForJob polls getStatus until it reports a final job state or ctx is done, sleeping
+// between polls with exponential backoff (starting at 1 second, doubling up to
+// pollMaxInterval) plus +/-20% jitter, so long-running playbooks aren't hammered with
+// requests while short ones are still noticed quickly.
+//
+// A getStatus error is treated as transient rather than fatal: waitForJob keeps polling
+// through it the same way it would a job that simply hasn't reached a final state yet, only
+// returning the error once ctx's deadline passes without a successful status check. This
+// mirrors the retryable-GET behavior of the retry.RetryContext loop this replaced, so a
+// flaky network blip during a long wait doesn't fail the apply outright.
+//
+// If subscriber is non-nil, a status_changed event for jobURL wakes the wait immediately
+// instead of sleeping out the current backoff. Either way, getStatus is always called
+// again to confirm the job's state via HTTP before waitForJob returns, so a dropped or
+// stale event can never be mistaken for the job having reached a final state.
+func waitForJob(ctx context.Context, jobURL string, pollMaxInterval time.Duration, subscriber *jobEventSubscriber, getStatus func() (string, error)) error {
+	var notify chan string
+	if subscriber != nil {
+		if jobID, err := jobIDFromURL(jobURL); err == nil {
+			notify = subscriber.acquire(jobID)
+			defer subscriber.release(jobID)
+		}
+	}
+
+	backoff := waitForJobInitialBackoff
+	var lastErr error
+	for {
+		status, err := getStatus()
+		switch {
+		case err != nil:
+			lastErr = err
+		case IsFinalStateAAPJob(status):
+			return nil
+		default:
+			lastErr = nil
+		}
+
+		jitter := time.Duration((rand.Float64()*2 - 1) * waitForJobJitterFraction * float64(backoff))
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return lastErr
+			}
+			return ctx.Err()
+		case <-notify:
+			// A status_changed event arrived; loop around immediately to reconfirm via HTTP.
+		case <-time.After(backoff + jitter):
+		}
+
+		if backoff < pollMaxInterval {
+			backoff *= 2
+			if backoff > pollMaxInterval {
+				backoff = pollMaxInterval
+			}
+		}
+	}
+}
+
+// jobIDFromURL extracts the numeric job id from a job's AAP API URL, e.g.
+// "/api/v2/jobs/34/" -> 34. AAP's status_changed websocket events identify jobs by id, not
+// URL, so this is how waitForJob keys its subscription.
+func jobIDFromURL(jobURL string) (int64, error) {
+	trimmed := strings.Trim(jobURL, "/")
+	segments := strings.Split(trimmed, "/")
+	last := segments[len(segments)-1]
+
+	id, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot extract job id from url %q: %s", jobURL, err)
+	}
+	return id, nil
+}
+
+// eventsWSURLProvider is implemented by *AAPClient when the provider is configured with
+// events_ws_url. Resources pass their *AAPClient to sharedJobEventSubscriber through it in
+// Configure to opt into event-driven waits instead of always falling back to plain backoff
+// polling.
+type eventsWSURLProvider interface {
+	EventsWSURL() string
+}
+
+var (
+	jobEventSubscribersMu sync.Mutex
+	jobEventSubscribers   = map[eventsWSURLProvider]*jobEventSubscriber{}
+)
+
+// sharedJobEventSubscriber returns the jobEventSubscriber shared by every resource
+// configured from client, creating it on first use. It returns nil if the provider wasn't
+// configured with events_ws_url, in which case waitForJob falls back to backoff polling.
+func sharedJobEventSubscriber(client eventsWSURLProvider) *jobEventSubscriber {
+	wsURL := client.EventsWSURL()
+	if wsURL == "" {
+		return nil
+	}
+
+	jobEventSubscribersMu.Lock()
+	defer jobEventSubscribersMu.Unlock()
+
+	if sub, ok := jobEventSubscribers[client]; ok {
+		return sub
+	}
+
+	sub := newJobEventSubscriber(wsURL, func() {
+		jobEventSubscribersMu.Lock()
+		delete(jobEventSubscribers, client)
+		jobEventSubscribersMu.Unlock()
+	})
+	jobEventSubscribers[client] = sub
+	return sub
+}
+
+// jobEventSubscriber maintains a single websocket subscription to AAP's "jobs" events
+// group and fans out status_changed messages to whichever waitForJob calls are currently
+// tracking each job. It is reference-counted across waiters so the connection opens on
+// the first waiter and closes once the last one releases it.
+type jobEventSubscriber struct {
+	wsURL   string
+	onClose func()
+
+	mu       sync.Mutex
+	refCount int
+	closed   bool
+	conn     *websocket.Conn
+	waiters  map[int64]chan string
+}
+
+func newJobEventSubscriber(wsURL string, onClose func()) *jobEventSubscriber {
+	return &jobEventSubscriber{
+		wsURL:   wsURL,
+		onClose: onClose,
+		waiters: make(map[int64]chan string),
+	}
+}
+
+// acquire registers a waiter for jobID, opening the shared connection if this is the
+// first waiter, and returns the channel that status_changed events for jobID are
+// delivered to.
+func (s *jobEventSubscriber) acquire(jobID int64) chan string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan string, 1)
+	s.waiters[jobID] = ch
+	s.refCount++
+	if s.refCount == 1 {
+		s.closed = false
+		go s.run()
+	}
+	return ch
+}
+
+// release unregisters the waiter for jobID, closing the shared connection once it was the
+// last one. If run's Dial hasn't returned yet when the last waiter releases, closed is left
+// set so run closes the connection itself as soon as it finishes dialing, instead of
+// installing it and leaking it in a blocked ReadJSON forever.
+func (s *jobEventSubscriber) release(jobID int64) {
+	s.mu.Lock()
+	delete(s.waiters, jobID)
+	s.refCount--
+	shouldClose := false
+	if s.refCount <= 0 {
+		s.refCount = 0
+		s.closed = true
+		if s.conn != nil {
+			_ = s.conn.Close()
+			s.conn = nil
+		}
+		shouldClose = true
+	}
+	s.mu.Unlock()
+
+	if shouldClose && s.onClose != nil {
+		s.onClose()
+	}
+}
+
+// wsStatusChangedMessage is the subset of AAP's websocket event payload this provider
+// cares about: a job's id and its new status.
+type wsStatusChangedMessage struct {
+	UnifiedJobID int64  `json:"unified_job_id"`
+	Status       string `json:"status"`
+}
+
+// run opens the shared connection, subscribes to the "jobs" group, and dispatches
+// status_changed messages to the waiter registered for each job until the connection is
+// closed by release or drops on its own; either way waiters fall back to backoff polling.
+func (s *jobEventSubscriber) run() {
+	conn, _, err := websocket.DefaultDialer.Dial(s.wsURL, nil)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		// The last waiter released while we were still dialing; there's nothing left to
+		// serve this connection, so close it immediately instead of leaking it.
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conn = conn
+	s.mu.Unlock()
+
+	if err := conn.WriteJSON(map[string]interface{}{
+		"groups": map[string][]string{"jobs": {"status_changed"}},
+	}); err != nil {
+		return
+	}
+
+	for {
+		var msg wsStatusChangedMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		ch, ok := s.waiters[msg.UnifiedJobID]
+		s.mu.Unlock()
+		if ok {
+			select {
+			case ch <- msg.Status:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ provider.Provider = &AAPProvider{}
+
+// AAPProvider is the provider implementation.
+type AAPProvider struct{}
+
+// AAPProviderModel maps the provider schema data.
+type AAPProviderModel struct {
+	Host               types.String `tfsdk:"host"`
+	Username           types.String `tfsdk:"username"`
+	Password           types.String `tfsdk:"password"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	EventsWSURL        types.String `tfsdk:"events_ws_url"`
+}
+
+// New is a helper function to simplify provider server and testing implementation.
+func New() provider.Provider {
+	return &AAPProvider{}
+}
+
+// Metadata returns the provider type name.
+func (p *AAPProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "aap"
+}
+
+// Schema defines the provider-level schema for configuration data.
+func (p *AAPProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"host": schema.StringAttribute{
+				Required:    true,
+				Description: "URL of the AAP controller, e.g. https://aap.example.com",
+			},
+			"username": schema.StringAttribute{
+				Required:    true,
+				Description: "Username to authenticate to the AAP controller with.",
+			},
+			"password": schema.StringAttribute{
+				Required:    true,
+				Sensitive:   true,
+				Description: "Password to authenticate to the AAP controller with.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Disable TLS certificate verification for the AAP controller. Defaults to `false`.",
+			},
+			"events_ws_url": schema.StringAttribute{
+				Optional: true,
+				Description: "Websocket URL for the AAP controller's job events feed, e.g. " +
+					"wss://aap.example.com/websocket/. When set, job and workflow job resources subscribe " +
+					"to status_changed events and wake their wait_for_completion polling as soon as a job " +
+					"changes state, instead of only on the next backoff interval. Optional; polling alone " +
+					"works fine without it.",
+			},
+		},
+	}
+}
+
+// Configure prepares an AAP API client for use by resources and data sources.
+func (p *AAPProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data AAPProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := NewAAPClient(
+		data.Host.ValueString(),
+		data.Username.ValueString(),
+		data.Password.ValueString(),
+		data.InsecureSkipVerify.ValueBool(),
+		data.EventsWSURL.ValueString(),
+	)
+
+	resp.ResourceData = client
+	resp.DataSourceData = client
+}
+
+// Resources defines the resources implemented by the provider.
+func (p *AAPProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewJobResource,
+		NewWorkflowJobResource,
+	}
+}
+
+// DataSources defines the data sources implemented by the provider.
+func (p *AAPProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{}
+}
@@ -0,0 +1,536 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ansible/terraform-provider-aap/internal/provider/customtypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// WorkflowJobAPIModel is the AAP API model for a workflow job.
+type WorkflowJobAPIModel struct {
+	TemplateID int64  `json:"workflow_job_template,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Inventory  int64  `json:"inventory,omitempty"`
+	ExtraVars  string `json:"extra_vars,omitempty"`
+}
+
+// WorkflowNodeAPIModel is the AAP API model for a single node of a workflow job. A node's
+// own status isn't reported by AAP; the status of the job it spawned is nested under
+// summary_fields.job instead.
+type WorkflowNodeAPIModel struct {
+	ID            int64  `json:"id,omitempty"`
+	URL           string `json:"url,omitempty"`
+	SummaryFields struct {
+		Job struct {
+			ID     int64  `json:"id,omitempty"`
+			Status string `json:"status,omitempty"`
+		} `json:"job"`
+	} `json:"summary_fields"`
+}
+
+// WorkflowNodeListAPIModel is the paginated response AAP returns when listing a workflow
+// job's nodes.
+type WorkflowNodeListAPIModel struct {
+	Next    string                 `json:"next"`
+	Results []WorkflowNodeAPIModel `json:"results"`
+}
+
+// workflowNodeAttrTypes describes the object type used for each entry of the workflow_nodes
+// computed list attribute.
+var workflowNodeAttrTypes = map[string]attr.Type{
+	"id":     types.Int64Type,
+	"url":    types.StringType,
+	"job_id": types.Int64Type,
+	"status": types.StringType,
+}
+
+// WorkflowJobResourceModel maps the aap_workflow_job resource schema data.
+type WorkflowJobResourceModel struct {
+	TemplateID                   types.Int64                      `tfsdk:"workflow_job_template_id"`
+	URL                          types.String                     `tfsdk:"url"`
+	Status                       types.String                     `tfsdk:"status"`
+	InventoryID                  types.Int64                      `tfsdk:"inventory_id"`
+	ExtraVars                    customtypes.AAPCustomStringValue `tfsdk:"extra_vars"`
+	Triggers                     types.Map                        `tfsdk:"triggers"`
+	WaitForCompletion            types.Bool                       `tfsdk:"wait_for_completion"`
+	WaitForCompletionTimeout     types.Int64                      `tfsdk:"wait_for_completion_timeout_seconds"`
+	DestroyWorkflowJobTemplateID types.Int64                      `tfsdk:"destroy_workflow_job_template_id"`
+	PollMaxIntervalSeconds       types.Int64                      `tfsdk:"poll_max_interval_seconds"`
+	WorkflowNodes                types.List                       `tfsdk:"workflow_nodes"`
+}
+
+// WorkflowJobResource is the resource implementation.
+type WorkflowJobResource struct {
+	client ProviderHTTPClient
+
+	// eventSubscriber delivers status_changed events for workflow jobs launched by this
+	// resource, so waitForWorkflowJobCompletion wakes immediately instead of always
+	// falling back to backoff polling. It is nil when the provider wasn't configured with
+	// events_ws_url.
+	eventSubscriber *jobEventSubscriber
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &WorkflowJobResource{}
+	_ resource.ResourceWithConfigure = &WorkflowJobResource{}
+)
+
+// NewWorkflowJobResource is a helper function to simplify the provider implementation.
+func NewWorkflowJobResource() resource.Resource {
+	return &WorkflowJobResource{}
+}
+
+// waitForWorkflowJobCompletion polls model.URL via client until the workflow job reaches a
+// final state or ctx's deadline passes, refreshing model in place on every poll. It wraps
+// the shared waitForJob helper with the WorkflowJobResourceModel-specific bits: parsing the
+// HTTP response and logging progress.
+func waitForWorkflowJobCompletion(ctx context.Context, client ProviderHTTPClient, model *WorkflowJobResourceModel, pollMaxInterval time.Duration, subscriber *jobEventSubscriber, diagnostics *diag.Diagnostics) error {
+	return waitForJob(ctx, model.URL.ValueString(), pollMaxInterval, subscriber, func() (string, error) {
+		responseBody, err := client.Get(model.URL.ValueString())
+		if err != nil {
+			return "", fmt.Errorf("error fetching workflow job status: %s", err)
+		}
+		diagnostics.Append(model.ParseHttpResponse(responseBody)...)
+		tflog.Debug(ctx, "polled AAP workflow job status", map[string]interface{}{
+			"workflow_job_template_id": model.TemplateID.String(),
+			"status":                   model.Status.ValueString(),
+		})
+		return model.Status.ValueString(), nil
+	})
+}
+
+// Metadata returns the resource type name.
+func (r *WorkflowJobResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_workflow_job"
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *WorkflowJobResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*AAPClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *AAPClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+	r.eventSubscriber = sharedJobEventSubscriber(client)
+}
+
+// Schema defines the schema for the workflow job resource.
+func (r *WorkflowJobResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"workflow_job_template_id": schema.Int64Attribute{
+				Required:    true,
+				Description: "Id of the workflow job template.",
+			},
+			"inventory_id": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+				Description: "Identifier for the inventory where the workflow job should be created in. " +
+					"If not provided, no inventory is sent with the launch request.",
+			},
+			"url": schema.StringAttribute{
+				Computed:    true,
+				Description: "URL of the workflow job",
+			},
+			"status": schema.StringAttribute{
+				Computed:    true,
+				Description: "Status of the workflow job",
+			},
+			"extra_vars": schema.StringAttribute{
+				Description: "Extra Variables. Must be provided as either a JSON or YAML string.",
+				Optional:    true,
+				CustomType:  customtypes.AAPCustomStringType{},
+			},
+			"triggers": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Map of arbitrary keys and values that, when changed, will trigger a creation" +
+					" of a new workflow job on AAP. Use 'terraform taint' if you want to force the creation of a" +
+					" new workflow job without changing this value.",
+			},
+			"wait_for_completion": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				Description: "When this is set to `true`, Terraform will wait until this aap_workflow_job resource " +
+					"is created, reaches any final status and then, proceeds with the following resource operation",
+			},
+			"wait_for_completion_timeout_seconds": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(waitForCompletionTimeoutDefault),
+				Description: "Sets the maximum amount of seconds Terraform will wait before timing out the updates, " +
+					"and the workflow job creation will fail. Default value of `120`",
+			},
+			"destroy_workflow_job_template_id": schema.Int64Attribute{
+				Optional: true,
+				Description: "Id of the workflow job template to run when the resource is destroyed. " +
+					"This allows running cleanup tasks before the resource is removed from the state.",
+			},
+			"poll_max_interval_seconds": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(pollMaxIntervalDefault),
+				Description: "Caps the exponential backoff between workflow job status polls while waiting " +
+					"for completion. Polling starts at 1 second and doubles (with +/-20% jitter) up to this " +
+					"value between each poll. Default value of `30`.",
+			},
+			"workflow_nodes": schema.ListAttribute{
+				Computed: true,
+				ElementType: types.ObjectType{
+					AttrTypes: workflowNodeAttrTypes,
+				},
+				Description: "The list of workflow nodes that make up this workflow job, along with their " +
+					"current status.",
+			},
+		},
+		MarkdownDescription: "Launches an AAP workflow job.\n\n" +
+			"A workflow job is launched only when the resource is first created or when the " +
+			"resource is changed. The " + "`triggers`" + " argument can be used to " +
+			"launch a new workflow job based on any arbitrary value.\n\n" +
+			"This resource always creates a new workflow job in AAP. A destroy will not " +
+			"delete a workflow job created by this resource, it will only remove the resource " +
+			"from the state. However, if `destroy_workflow_job_template_id` is specified, " +
+			"it will launch that workflow job template during resource destruction, allowing " +
+			"you to run cleanup tasks before the resource is removed.\n\n" +
+			"Moreover, you can set `wait_for_completion` to true, then Terraform will " +
+			"wait until this workflow job is created and reaches any final state before continuing. " +
+			"This parameter works in both create and update operations.\n\n" +
+			"You can also tweak `wait_for_completion_timeout_seconds` to control the timeout limit, and " +
+			"`poll_max_interval_seconds` to control how far polling backs off between status checks " +
+			"while waiting.",
+	}
+}
+
+func (r *WorkflowJobResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data WorkflowJobResourceModel
+
+	// Read Terraform plan data into workflow job resource model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.LaunchJob(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// If the workflow job was configured to wait for completion, start polling the job status
+	// and wait for it to complete before marking the resource as created
+	if data.WaitForCompletion.ValueBool() {
+		r.waitForCompletion(ctx, &data, "error when waiting for AAP workflow job to complete", &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.RefreshWorkflowNodes(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *WorkflowJobResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data WorkflowJobResourceModel
+
+	// Read current Terraform state data into workflow job resource model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get latest workflow job data from AAP
+	readResponseBody, diags, status := r.client.GetWithStatus(data.URL.ValueString())
+
+	// Check if the response is 404, meaning the workflow job does not exist and should be recreated
+	if status == http.StatusNotFound {
+		resp.Diagnostics.AddWarning(
+			"Workflow job not found",
+			"The workflow job was not found. It may have been deleted. The workflow job will be recreated.",
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save latest workflow job data into workflow job resource model
+	resp.Diagnostics.Append(data.ParseHttpResponse(readResponseBody)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.RefreshWorkflowNodes(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *WorkflowJobResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data WorkflowJobResourceModel
+
+	// Read Terraform plan data into workflow job resource model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create new workflow job from workflow job template
+	resp.Diagnostics.Append(r.LaunchJob(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// If the workflow job was configured to wait for completion, start polling the job status
+	// and wait for it to complete before marking the resource as created
+	if data.WaitForCompletion.ValueBool() {
+		r.waitForCompletion(ctx, &data, "error when waiting for AAP workflow job to complete", &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.RefreshWorkflowNodes(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete launches a destroy workflow job template if configured, otherwise just removes the resource from state.
+func (r WorkflowJobResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data WorkflowJobResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// If destroy_workflow_job_template_id is specified, launch that workflow job template
+	if !data.DestroyWorkflowJobTemplateID.IsNull() && data.DestroyWorkflowJobTemplateID.ValueInt64() > 0 {
+		// Create a temporary model for the destroy workflow job
+		destroyJobData := WorkflowJobResourceModel{
+			TemplateID:               data.DestroyWorkflowJobTemplateID,
+			InventoryID:              data.InventoryID,
+			ExtraVars:                data.ExtraVars,
+			WaitForCompletion:        data.WaitForCompletion,
+			WaitForCompletionTimeout: data.WaitForCompletionTimeout,
+			PollMaxIntervalSeconds:   data.PollMaxIntervalSeconds,
+		}
+
+		// Launch the destroy workflow job
+		resp.Diagnostics.Append(r.LaunchJob(&destroyJobData)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// If configured to wait for completion, wait for the destroy workflow job to finish
+		if destroyJobData.WaitForCompletion.ValueBool() {
+			r.waitForCompletion(ctx, &destroyJobData, "error when waiting for destroy workflow job to complete", &resp.Diagnostics)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+}
+
+// CreateRequestBody creates a JSON encoded request body from the workflow job resource data
+func (r *WorkflowJobResourceModel) CreateRequestBody() ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// Unlike a plain job template, a workflow job template frequently has no inventory
+	// prompt at all, so inventory is omitted (via the Inventory field's omitempty tag)
+	// rather than defaulted to 1 when the user didn't set one.
+	job := WorkflowJobAPIModel{
+		ExtraVars: r.ExtraVars.ValueString(),
+		Inventory: r.InventoryID.ValueInt64(),
+	}
+
+	// Create JSON encoded request body
+	jsonBody, err := json.Marshal(job)
+	if err != nil {
+		diags.AddError(
+			"Error marshaling request body",
+			fmt.Sprintf("Could not create request body for workflow job resource, unexpected error: %s", err.Error()),
+		)
+		return nil, diags
+	}
+	return jsonBody, diags
+}
+
+// ParseHttpResponse updates the workflow job resource data from an AAP API response
+func (r *WorkflowJobResourceModel) ParseHttpResponse(body []byte) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// Unmarshal the JSON response
+	var resultAPIJob WorkflowJobAPIModel
+	err := json.Unmarshal(body, &resultAPIJob)
+	if err != nil {
+		diags.AddError("Error parsing JSON response from AAP", err.Error())
+		return diags
+	}
+
+	// Map response to the workflow job resource schema and update attribute values
+	r.URL = types.StringValue(resultAPIJob.URL)
+	r.Status = types.StringValue(resultAPIJob.Status)
+	r.TemplateID = types.Int64Value(resultAPIJob.TemplateID)
+	r.InventoryID = types.Int64Value(resultAPIJob.Inventory)
+	return diags
+}
+
+func (r *WorkflowJobResource) LaunchJob(data *WorkflowJobResourceModel) diag.Diagnostics {
+	// Create new workflow job from workflow job template
+	var diags diag.Diagnostics
+
+	// Create request body from workflow job data
+	requestBody, diagCreateReq := data.CreateRequestBody()
+	diags.Append(diagCreateReq...)
+	if diags.HasError() {
+		return diags
+	}
+
+	requestData := bytes.NewReader(requestBody)
+	var postURL = path.Join(r.client.getApiEndpoint(), "workflow_job_templates", data.GetTemplateID(), "launch")
+	resp, body, err := r.client.doRequest(http.MethodPost, postURL, requestData)
+	diags.Append(ValidateResponse(resp, body, err, []int{http.StatusCreated})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Save new workflow job data into workflow job resource model
+	diags.Append(data.ParseHttpResponse(body)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	return diags
+}
+
+func (r *WorkflowJobResourceModel) GetTemplateID() string {
+	return r.TemplateID.String()
+}
+
+// waitForCompletion waits for data's workflow job to reach a final state, honoring
+// wait_for_completion_timeout_seconds and poll_max_interval_seconds, and appends summary
+// as an error diagnostic if the wait itself fails (times out, loses the connection, etc).
+func (r *WorkflowJobResource) waitForCompletion(ctx context.Context, data *WorkflowJobResourceModel, summary string, diagnostics *diag.Diagnostics) {
+	timeout := time.Duration(data.WaitForCompletionTimeout.ValueInt64()) * time.Second
+	pollMaxInterval := time.Duration(data.PollMaxIntervalSeconds.ValueInt64()) * time.Second
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := waitForWorkflowJobCompletion(waitCtx, r.client, data, pollMaxInterval, r.eventSubscriber, diagnostics); err != nil {
+		diagnostics.Append(diag.NewErrorDiagnostic(summary, err.Error()))
+	}
+}
+
+// RefreshWorkflowNodes fetches the child workflow_nodes for this workflow job, following
+// pagination until every node has been collected, and stores their per-node status (read
+// from summary_fields.job, since a node has no status of its own) in the workflow_nodes
+// computed attribute.
+func (r *WorkflowJobResource) RefreshWorkflowNodes(data *WorkflowJobResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if data.URL.ValueString() == "" {
+		data.WorkflowNodes = types.ListNull(types.ObjectType{AttrTypes: workflowNodeAttrTypes})
+		return diags
+	}
+
+	var nodes []WorkflowNodeAPIModel
+	nodesURL := strings.TrimSuffix(data.URL.ValueString(), "/") + "/workflow_nodes/"
+	for nodesURL != "" {
+		body, err := r.client.Get(nodesURL)
+		if err != nil {
+			diags.AddError("Error fetching workflow job nodes", err.Error())
+			return diags
+		}
+
+		var nodeList WorkflowNodeListAPIModel
+		if err := json.Unmarshal(body, &nodeList); err != nil {
+			diags.AddError("Error parsing JSON response from AAP", err.Error())
+			return diags
+		}
+
+		nodes = append(nodes, nodeList.Results...)
+		nodesURL = nodeList.Next
+	}
+
+	nodeValues := []attr.Value{}
+	for _, node := range nodes {
+		nodeObject, objDiags := types.ObjectValue(workflowNodeAttrTypes, map[string]attr.Value{
+			"id":     types.Int64Value(node.ID),
+			"url":    types.StringValue(node.URL),
+			"job_id": types.Int64Value(node.SummaryFields.Job.ID),
+			"status": types.StringValue(node.SummaryFields.Job.Status),
+		})
+		diags.Append(objDiags...)
+		nodeValues = append(nodeValues, nodeObject)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	nodesList, listDiags := types.ListValue(types.ObjectType{AttrTypes: workflowNodeAttrTypes}, nodeValues)
+	diags.Append(listDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	data.WorkflowNodes = nodesList
+	return diags
+}